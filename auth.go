@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/containers/image/v5/types"
+)
+
+// RepositoryAuth configures how a Repository authenticates against its
+// registry for push. Exactly one of AuthFile, CredentialHelper or
+// Username/PasswordEnv is expected to be set.
+type RepositoryAuth struct {
+	AuthFile         string `json:"authFile,omitempty"`
+	CredentialHelper string `json:"credentialHelper,omitempty"`
+	Username         string `json:"username,omitempty"`
+	PasswordEnv      string `json:"passwordEnv,omitempty"`
+}
+
+// BuildSystemContext turns repo.Auth into the types.SystemContext that
+// CopyImageNative's containers/image calls need to authenticate a push to
+// repo.Registry. It returns an empty, unauthenticated context when repo.Auth
+// is nil. The returned cleanup func removes any temporary file BuildSystemContext
+// created (e.g. for CredentialHelper) and must be called once the push is
+// done; it is a no-op otherwise.
+func BuildSystemContext(repo Repository) (ctx *types.SystemContext, cleanup func(), err error) {
+	ctx = &types.SystemContext{}
+	cleanup = func() {}
+
+	if repo.Auth == nil {
+		return ctx, cleanup, nil
+	}
+
+	switch {
+	case repo.Auth.AuthFile != "":
+		ctx.AuthFilePath = repo.Auth.AuthFile
+
+	case repo.Auth.CredentialHelper != "":
+		authFile, err := writeCredentialHelperAuthFile(repo.Registry, repo.Auth.CredentialHelper)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("configuring credential helper for %s: %w", repo.Registry, err)
+		}
+		ctx.AuthFilePath = authFile
+		cleanup = func() { os.Remove(authFile) }
+
+	case repo.Auth.Username != "":
+		if repo.Auth.PasswordEnv == "" {
+			return nil, cleanup, fmt.Errorf("repository %s: auth.username set without auth.passwordEnv", repo.Name)
+		}
+
+		password := os.Getenv(repo.Auth.PasswordEnv)
+		if password == "" {
+			return nil, cleanup, fmt.Errorf("repository %s: environment variable %s referenced by auth.passwordEnv is empty", repo.Name, repo.Auth.PasswordEnv)
+		}
+
+		ctx.DockerAuthConfig = &types.DockerAuthConfig{
+			Username: repo.Auth.Username,
+			Password: password,
+		}
+	}
+
+	return ctx, cleanup, nil
+}
+
+// FindRepository looks up the Repository matching destination by Name or
+// AdditionalNames, the same rule GetDestination uses, so callers can look up
+// its Auth config. It returns nil for raw "!"-prefixed destinations, which
+// bypass config.json entirely.
+func FindRepository(destination string, config Config) *Repository {
+	for i, repo := range config.Repositories {
+		if repo.Name == destination {
+			return &config.Repositories[i]
+		}
+
+		for _, additional := range repo.AdditionalNames {
+			if additional == destination {
+				return &config.Repositories[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeCredentialHelperAuthFile renders a minimal docker/config.json-style
+// auth file pointing registry at the docker-credential-<helper> binary,
+// since that's the file containers/image and podman read credential helper
+// configuration from.
+func writeCredentialHelperAuthFile(registry string, helper string) (string, error) {
+	authFile, err := os.CreateTemp("", "container-image-distributor-authfile-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer authFile.Close()
+
+	contents := struct {
+		CredHelpers map[string]string `json:"credHelpers"`
+	}{
+		CredHelpers: map[string]string{registry: helper},
+	}
+
+	if err := json.NewEncoder(authFile).Encode(contents); err != nil {
+		return "", err
+	}
+
+	return authFile.Name(), nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestBuildSystemContext_CredentialHelper(t *testing.T) {
+	repo := Repository{
+		Name:     "my-registry",
+		Registry: "myregistry.io",
+		Auth:     &RepositoryAuth{CredentialHelper: "ecr-login"},
+	}
+
+	ctx, cleanup, err := BuildSystemContext(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if ctx.AuthFilePath == "" {
+		t.Fatal("expected AuthFilePath to be set")
+	}
+
+	data, err := os.ReadFile(ctx.AuthFilePath)
+	if err != nil {
+		t.Fatalf("reading auth file: %v", err)
+	}
+
+	var contents struct {
+		CredHelpers map[string]string `json:"credHelpers"`
+	}
+	if err := json.Unmarshal(data, &contents); err != nil {
+		t.Fatalf("parsing auth file: %v", err)
+	}
+	if got := contents.CredHelpers["myregistry.io"]; got != "ecr-login" {
+		t.Errorf("credHelpers[myregistry.io] = %q, want %q", got, "ecr-login")
+	}
+
+	authFilePath := ctx.AuthFilePath
+	cleanup()
+	if _, err := os.Stat(authFilePath); !os.IsNotExist(err) {
+		t.Errorf("expected auth file %s to be removed after cleanup, stat err = %v", authFilePath, err)
+	}
+}
+
+func TestBuildSystemContext_UsernameWithoutPasswordEnv(t *testing.T) {
+	repo := Repository{
+		Name: "my-registry",
+		Auth: &RepositoryAuth{Username: "alice"},
+	}
+
+	_, _, err := BuildSystemContext(repo)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+func TestBuildSystemContext_EmptyPasswordEnv(t *testing.T) {
+	const envVar = "CONTAINER_IMAGE_DISTRIBUTOR_TEST_PASSWORD"
+	os.Unsetenv(envVar)
+
+	repo := Repository{
+		Name: "my-registry",
+		Auth: &RepositoryAuth{Username: "alice", PasswordEnv: envVar},
+	}
+
+	_, _, err := BuildSystemContext(repo)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/containers/image/v5/types"
+)
+
+// BatchJob is one row of a -batch input file.
+type BatchJob struct {
+	SourceImage string `json:"sourceImage"`
+	Destination string `json:"destination"`
+	OverrideTag string `json:"overrideTag,omitempty"`
+}
+
+// BatchResult is one row of the JSON report a -batch run prints to stdout.
+type BatchResult struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Status      string `json:"status"`
+	DurationMs  int64  `json:"durationMs"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ReadBatchJobs parses a -batch input file, accepting either a single JSON
+// array of BatchJob or newline-delimited JSON objects (one BatchJob per
+// line).
+func ReadBatchJobs(path string) ([]BatchJob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	var jobs []BatchJob
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &jobs); err != nil {
+			return nil, fmt.Errorf("parsing %s as a JSON array: %w", path, err)
+		}
+		return jobs, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var job BatchJob
+		if err := json.Unmarshal(line, &job); err != nil {
+			return nil, fmt.Errorf("parsing %s line %q: %w", path, line, err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// RunBatch processes jobs with up to parallel concurrent workers, running
+// each through the same destination-resolution and pull/tag/push (or native
+// copy) pipeline a single -image invocation would use, and returns one
+// BatchResult per job in job order. A job failing (including
+// RepositoryNotFoundForSourceError/RepositoryNotFoundForDestinationError) is
+// recorded in its own result rather than aborting the rest of the batch.
+func RunBatch(jobs []BatchJob, parallel int, containerTool string, copyBackend string, config Config) []BatchResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]BatchResult, len(jobs))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, job BatchJob) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runBatchJob(job, containerTool, copyBackend, config)
+		}(i, job)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func runBatchJob(job BatchJob, containerTool string, copyBackend string, config Config) BatchResult {
+	start := time.Now()
+	result := BatchResult{Source: job.SourceImage, Destination: job.Destination}
+
+	err := func() error {
+		destinationImage, err := GenerateDestinationPathFromSourcePathAndConfig(job.SourceImage, job.Destination, config)
+		if err != nil {
+			return err
+		}
+
+		destinationImage = ApplyDestinationMapping(destinationImage, config.DestinationMapping)
+		if job.OverrideTag != "" {
+			destinationImage = OverrideTag(job.OverrideTag, destinationImage)
+		}
+		result.Destination = destinationImage
+
+		destCtx := &types.SystemContext{}
+		var authFilePath string
+		if repo := FindRepository(job.Destination, config); repo != nil {
+			var cleanup func()
+			destCtx, cleanup, err = BuildSystemContext(*repo)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			authFilePath = destCtx.AuthFilePath
+		}
+
+		return CopyOrPushImage(containerTool, copyBackend, job.SourceImage, destinationImage, destCtx, authFilePath)
+	}()
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	} else {
+		result.Status = "ok"
+	}
+
+	return result
+}
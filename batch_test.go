@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTempBatchFile(t *testing.T, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("writing temp batch file: %v", err)
+	}
+	return path
+}
+
+func TestReadBatchJobs_JSONArray(t *testing.T) {
+	path := writeTempBatchFile(t, `[
+		{"sourceImage": "alpine", "destination": "docker-hub"},
+		{"sourceImage": "ubuntu", "destination": "!registry.example.com/ubuntu", "overrideTag": "latest"}
+	]`)
+
+	got, err := ReadBatchJobs(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []BatchJob{
+		{SourceImage: "alpine", Destination: "docker-hub"},
+		{SourceImage: "ubuntu", Destination: "!registry.example.com/ubuntu", OverrideTag: "latest"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestReadBatchJobs_NDJSON(t *testing.T) {
+	path := writeTempBatchFile(t, `{"sourceImage": "alpine", "destination": "docker-hub"}
+{"sourceImage": "ubuntu", "destination": "!registry.example.com/ubuntu", "overrideTag": "latest"}
+`)
+
+	got, err := ReadBatchJobs(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []BatchJob{
+		{SourceImage: "alpine", Destination: "docker-hub"},
+		{SourceImage: "ubuntu", Destination: "!registry.example.com/ubuntu", OverrideTag: "latest"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestReadBatchJobs_MalformedLine(t *testing.T) {
+	path := writeTempBatchFile(t, `{"sourceImage": "alpine", "destination": "docker-hub"}
+not json
+`)
+
+	if _, err := ReadBatchJobs(path); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
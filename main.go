@@ -10,8 +10,24 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/containers/image/v5/types"
+	"github.com/distribution/reference"
 )
 
+// repeatedFlag collects every occurrence of a flag.Var flag, used by -image
+// to accept one entry per platform under -manifest.
+type repeatedFlag []string
+
+func (f *repeatedFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *repeatedFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 // Structs
 
 type Repository struct {
@@ -20,6 +36,7 @@ type Repository struct {
 	Registry           string            `json:"registry"`
 	Suffix             string            `json:"suffix,omitempty"`
 	DestinationMapping map[string]string `json:"destinationMappings,omitempty"`
+	Auth               *RepositoryAuth   `json:"auth,omitempty"`
 }
 
 func (r Repository) GetRegistryPath() string {
@@ -53,18 +70,39 @@ func (e RepositoryNotFoundForSourceError) Error() string {
 	return fmt.Sprintf("Could not find repository matching %s source image", e.source)
 }
 
+type TransportSourceRequiresRawDestinationError struct {
+	source string
+}
+
+func (e TransportSourceRequiresRawDestinationError) Error() string {
+	return fmt.Sprintf(
+		"%s names a non-registry transport and has no registry path to match against a config.json repository; "+
+			"pass a \"!\"-prefixed raw destination instead",
+		e.source,
+	)
+}
+
 func main() {
 	var containerTool string
-	var sourceImage string
+	var copyBackend string
+	var sourceImages repeatedFlag
 	var destination string
 	var overrideTag string
 	var force bool
+	var manifest bool
+	var platformsFlag string
+	var batchFile string
+	var parallel int
 
 	flag.StringVar(&containerTool, "container-tool", "docker", "podman/docker")
 	flag.StringVar(&containerTool, "c", containerTool, "alias for -container-tool")
 
-	flag.StringVar(&sourceImage, "image", "", "image that will be used")
-	flag.StringVar(&sourceImage, "i", sourceImage, "alias for -image")
+	flag.StringVar(&copyBackend, "copy-backend", "exec",
+		"how to move the image: \"exec\" shells out to -container-tool, \"containers-image\" copies "+
+			"directly via github.com/containers/image/v5 without a local daemon")
+
+	flag.Var(&sourceImages, "image", "image that will be used; repeat for one entry per -platforms architecture under -manifest")
+	flag.Var(&sourceImages, "i", "alias for -image")
 
 	flag.StringVar(&destination, "destination-repository", "",
 		"destination repository which will be picked from \"config.json\" based on repository \"name\" or \"additionalNames\". "+
@@ -77,11 +115,44 @@ func main() {
 	flag.BoolVar(&force, "force", false, "push image without asking for destination path verification")
 	flag.BoolVar(&force, "f", force, "alias for -force")
 
+	flag.BoolVar(&manifest, "manifest", false,
+		"assemble a multi-arch manifest list from one -image per -platforms entry and push it instead of a single image")
+	flag.StringVar(&platformsFlag, "platforms", "",
+		"comma-separated os/arch[/variant] list, one per -image, required when -manifest is set")
+
+	flag.StringVar(&batchFile, "batch", "",
+		"process a newline- or JSON-array-delimited list of {sourceImage, destination, overrideTag} jobs "+
+			"read from this file instead of a single -image/-destination-repository; implies -force")
+	flag.IntVar(&parallel, "parallel", 1, "number of concurrent workers to use under -batch")
+
 	flag.Parse()
 
-	if sourceImage == "" {
+	// Load config
+	config, err := LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if batchFile != "" {
+		jobs, err := ReadBatchJobs(batchFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		results := RunBatch(jobs, parallel, containerTool, copyBackend, config)
+
+		report, err := json.Marshal(results)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(report))
+		return
+	}
+
+	if len(sourceImages) == 0 {
 		log.Fatal("Must specify -image")
 	}
+	sourceImage := sourceImages[0]
 
 	if containerTool == "" {
 		log.Fatal("Must specify -container-tool")
@@ -91,10 +162,13 @@ func main() {
 		log.Fatal("Must specify -destination")
 	}
 
-	// Load config
-	config, err := LoadConfig()
-	if err != nil {
-		log.Fatal(err)
+	if manifest && platformsFlag == "" {
+		log.Fatal("Must specify -platforms when -manifest is set")
+	}
+
+	if manifest && (copyBackend == "containers-image" || containerTool == "native") {
+		log.Fatal("-manifest only supports the exec backend (docker/podman manifest commands); " +
+			"-copy-backend=containers-image and -container-tool=native are not supported with -manifest")
 	}
 
 	// Generate destinationImage
@@ -121,47 +195,133 @@ func main() {
 		}
 	}
 
+	// Resolve the destination repository's auth config, if any.
+	var authFilePath string
+	destCtx := &types.SystemContext{}
+	if repo := FindRepository(destination, config); repo != nil {
+		var cleanup func()
+		destCtx, cleanup, err = BuildSystemContext(*repo)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer cleanup()
+		authFilePath = destCtx.AuthFilePath
+	}
+
+	if manifest {
+		platforms, err := ParsePlatforms(platformsFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Println("Pushing manifest list...")
+		if err := PushManifestList(containerTool, sourceImages, platforms, destinationImage, authFilePath); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := CopyOrPushImage(containerTool, copyBackend, sourceImage, destinationImage, destCtx, authFilePath); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// CopyOrPushImage moves sourceImage to destinationImage using whichever
+// backend applies: the containers/image native copy when -copy-backend,
+// -container-tool=native or a non-registry transport calls for it, otherwise
+// the existing pull/tag/push via the -container-tool exec backend. Transport
+// refs (docker-archive:, oci-archive:, dir:) unconditionally route to
+// CopyImageNative since there's no exec-backend equivalent for them, so they
+// depend on CopyImageNative staying usable without docker/podman/skopeo
+// installed (see the in-memory policy built in native_copy.go).
+func CopyOrPushImage(containerTool string, copyBackend string, sourceImage string, destinationImage string, destCtx *types.SystemContext, authFilePath string) error {
+	usesTransport := HasTransportPrefix(sourceImage) || HasTransportPrefix(destinationImage)
+	if usesTransport && copyBackend != "containers-image" {
+		log.Println("Source or destination names a non-registry transport; switching to -copy-backend=containers-image.")
+	}
+
+	if copyBackend == "containers-image" || containerTool == "native" || usesTransport {
+		log.Println("Copying image (containers/image, no daemon required)...")
+		return CopyImageNative(sourceImage, destinationImage, destCtx)
+	}
+
 	log.Println("Pulling image...")
 	result, err := PullImage(containerTool, sourceImage)
 	log.Println(result)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	log.Println("Tagging image...")
 	result, err = TagImage(containerTool, sourceImage, destinationImage)
 	log.Println(result)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	log.Println("Pushing image...")
-	result, err = PushImage(containerTool, destinationImage)
+	result, err = PushImage(containerTool, destinationImage, authFilePath)
 	log.Println(result)
-	if err != nil {
-		log.Fatal(err)
-	}
+	return err
 }
 
 // Image destination logic
 
 func GenerateDestinationPathFromSourcePathAndConfig(image string, destination string, config Config) (string, error) {
-	imageParts := strings.Split(image, "/")
+	if HasTransportPrefix(image) {
+		// e.g. "docker-archive:/path/to.tar" or "dir:/path": there's no
+		// registry to match a repository against, so only a raw "!"
+		// destination (bypassing config.json) makes sense here.
+		if !strings.HasPrefix(destination, "!") {
+			return "", TransportSourceRequiresRawDestinationError{source: image}
+		}
+		return GetDestination(destination, image, config)
+	}
 
-	if len(imageParts) < 2 {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		// Not a plain registry reference; fall back to matching the raw string.
 		return GetDestination(destination, image, config)
 	}
 
+	domain := reference.Domain(named)
+	path := reference.Path(named)
+
 	for _, repo := range config.Repositories {
-		imageWithoutRegistry, found := strings.CutPrefix(image, repo.GetRegistryPath()+"/")
-		if found {
-			return GetDestination(destination, imageWithoutRegistry, config)
+		if domain != repo.Registry {
+			continue
 		}
+
+		imagePath := path
+		if repo.Suffix != "" {
+			var found bool
+			imagePath, found = strings.CutPrefix(path, repo.Suffix+"/")
+			if !found {
+				continue
+			}
+		}
+
+		return GetDestination(destination, imagePath+tagOrDigestSuffix(named), config)
 	}
 
 	return "", RepositoryNotFoundForSourceError{image}
 }
 
+// tagOrDigestSuffix returns the ":tag" or "@digest" portion of named, so
+// callers that reassemble an image string from reference.Path(named)
+// don't lose it.
+func tagOrDigestSuffix(named reference.Named) string {
+	if tagged, ok := named.(reference.Tagged); ok {
+		return ":" + tagged.Tag()
+	}
+
+	if digested, ok := named.(reference.Digested); ok {
+		return "@" + digested.Digest().String()
+	}
+
+	return ""
+}
+
 func GetDestination(destination string, imagePathWithoutRegistry string, config Config) (string, error) {
 	if strings.HasPrefix(destination, "!") {
 		return strings.Replace(destination, "!", "", 1), nil
@@ -194,15 +354,22 @@ func ApplyDestinationMapping(path string, mapping map[string]string) string {
 }
 
 func OverrideTag(tag string, image string) string {
-	// Find the last colon
-	if i := strings.LastIndex(image, ":"); i != -1 {
-		// Check if there's a "/" after the colon, which means this colon might be part of the registry/namespace.
-		if strings.Index(image[i:], "/") == -1 {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		// Not a parseable reference (e.g. it carries a transport prefix);
+		// fall back to the previous string-based behaviour.
+		if i := strings.LastIndex(image, ":"); i != -1 && strings.Index(image[i:], "/") == -1 {
 			return image[:i] + ":" + tag
 		}
+		return image + ":" + tag
 	}
 
-	return image + ":" + tag
+	tagged, err := reference.WithTag(named, tag)
+	if err != nil {
+		return image + ":" + tag
+	}
+
+	return reference.FamiliarString(tagged)
 }
 
 // Container Tool
@@ -220,8 +387,18 @@ func TagImage(containerTool string, srcImage string, destImage string) (string,
 	return string(out), err
 }
 
-func PushImage(containerTool string, image string) (string, error) {
-	command := exec.Command(containerTool, "push", image)
+func PushImage(containerTool string, image string, authFilePath string) (string, error) {
+	args := []string{"push", image}
+	if authFilePath != "" {
+		// docker push has no --authfile equivalent; podman does. Fail
+		// clearly instead of letting exec.Command reject the flag.
+		if containerTool != "podman" {
+			return "", fmt.Errorf("-container-tool=%s does not support --authfile; set -container-tool=podman or -copy-backend=containers-image to use this repository's auth config", containerTool)
+		}
+		args = append(args, "--authfile", authFilePath)
+	}
+
+	command := exec.Command(containerTool, args...)
 	out, err := command.CombinedOutput()
 	return string(out), err
 }
@@ -0,0 +1,144 @@
+package main
+
+import "testing"
+
+func TestGenerateDestinationPathFromSourcePathAndConfig(t *testing.T) {
+	config := Config{
+		Repositories: []Repository{
+			{Name: "docker-hub", Registry: "docker.io"},
+			{Name: "my-registry", Registry: "myregistry.io", Suffix: "team"},
+			{Name: "short", Registry: "my"},
+			{Name: "local", Registry: "localhost:5000"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		image       string
+		destination string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "default registry image without explicit domain",
+			image:       "alpine",
+			destination: "docker-hub",
+			want:        "docker.io/library/alpine",
+		},
+		{
+			name:        "digest is preserved",
+			image:       "docker.io/library/alpine@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			destination: "docker-hub",
+			want:        "docker.io/library/alpine@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		},
+		{
+			name:        "registry with a port",
+			image:       "localhost:5000/foo:v1",
+			destination: "local",
+			want:        "localhost:5000/foo:v1",
+		},
+		{
+			name:        "suffix must match as a path prefix",
+			image:       "myregistry.io/team/foo:v1",
+			destination: "my-registry",
+			want:        "myregistry.io/team/foo:v1",
+		},
+		{
+			name:        "registry domain must match exactly, not as a string prefix",
+			image:       "myregistry.io/foo:v1",
+			destination: "short",
+			wantErr:     true,
+		},
+		{
+			name:        "transport source with a raw destination passes through",
+			image:       "docker-archive:/path/to.tar",
+			destination: "!docker://myregistry.io/foo:v1",
+			want:        "docker://myregistry.io/foo:v1",
+		},
+		{
+			name:        "transport source with a config-matched destination is rejected",
+			image:       "docker-archive:/path/to.tar",
+			destination: "docker-hub",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateDestinationPathFromSourcePathAndConfig(tt.image, tt.destination, config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got destination %q", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverrideTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		tag   string
+		image string
+		want  string
+	}{
+		{
+			name:  "default registry image keeps its familiar form",
+			tag:   "v2",
+			image: "alpine",
+			want:  "alpine:v2",
+		},
+		{
+			name:  "registry with a port",
+			tag:   "new",
+			image: "localhost:5000/foo:old",
+			want:  "localhost:5000/foo:new",
+		},
+		{
+			name:  "non-parseable transport reference falls back to string replacement",
+			tag:   "new",
+			image: "docker-archive:/path/foo:old",
+			want:  "docker-archive:/path/foo:new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OverrideTag(tt.tag, tt.image)
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasTransportPrefix(t *testing.T) {
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{"docker-archive:/path/to.tar", true},
+		{"oci-archive:/path:tag", true},
+		{"dir:/path", true},
+		{"docker://alpine", true},
+		{"myregistry.io/foo:tag", false},
+		{"localhost:5000/foo", false},
+		{"alpine", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := HasTransportPrefix(tt.image); got != tt.want {
+				t.Errorf("HasTransportPrefix(%q) = %v, want %v", tt.image, got, tt.want)
+			}
+		})
+	}
+}
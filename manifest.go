@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Platform describes the OS/architecture/variant triple used to annotate
+// one entry of a multi-arch manifest list, as accepted by -platforms.
+type Platform struct {
+	OS      string
+	Arch    string
+	Variant string
+}
+
+func (p Platform) String() string {
+	if p.Variant == "" {
+		return p.OS + "/" + p.Arch
+	}
+
+	return p.OS + "/" + p.Arch + "/" + p.Variant
+}
+
+// ParsePlatforms parses the comma-separated "-platforms" flag value into one
+// Platform per "os/arch[/variant]" entry.
+func ParsePlatforms(spec string) ([]Platform, error) {
+	var platforms []Platform
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.Split(entry, "/")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid platform %q, expected os/arch or os/arch/variant", entry)
+		}
+
+		platform := Platform{OS: parts[0], Arch: parts[1]}
+		if len(parts) == 3 {
+			platform.Variant = parts[2]
+		}
+
+		platforms = append(platforms, platform)
+	}
+
+	return platforms, nil
+}
+
+// PushManifestList pulls and tags each per-arch image exactly like the
+// single-image flow, then assembles them into an image index under
+// destinationImage and pushes that too. It follows the podman-manifest
+// workflow: create a local manifest list, manifest-add each per-arch image
+// with its platform annotation, then manifest-push the list.
+func PushManifestList(containerTool string, images []string, platforms []Platform, destinationImage string, authFilePath string) error {
+	if len(images) != len(platforms) {
+		return fmt.Errorf("got %d -image flags but %d -platforms entries, they must match 1:1", len(images), len(platforms))
+	}
+
+	for _, image := range images {
+		if result, err := PullImage(containerTool, image); err != nil {
+			return fmt.Errorf("pulling %s: %w: %s", image, err, result)
+		}
+	}
+
+	// Best-effort: the list may already exist from a previous run.
+	_, _ = runManifestCommand(containerTool, "rm", destinationImage)
+
+	if result, err := runManifestCommand(containerTool, "create", destinationImage); err != nil {
+		return fmt.Errorf("creating manifest list %s: %w: %s", destinationImage, err, result)
+	}
+
+	for i, image := range images {
+		platform := platforms[i]
+		args := []string{"add", "--os", platform.OS, "--arch", platform.Arch}
+		if platform.Variant != "" {
+			args = append(args, "--variant", platform.Variant)
+		}
+		args = append(args, destinationImage, image)
+
+		if result, err := runManifestCommand(containerTool, args...); err != nil {
+			return fmt.Errorf("adding %s (%s) to manifest list: %w: %s", image, platform, err, result)
+		}
+	}
+
+	pushArgs := []string{"push", destinationImage}
+	if authFilePath != "" {
+		// docker manifest push has no --authfile equivalent; podman does.
+		if containerTool != "podman" {
+			return fmt.Errorf("-container-tool=%s does not support --authfile; set -container-tool=podman to use this repository's auth config", containerTool)
+		}
+		pushArgs = append(pushArgs, "--authfile", authFilePath)
+	}
+	if result, err := runManifestCommand(containerTool, pushArgs...); err != nil {
+		return fmt.Errorf("pushing manifest list %s: %w: %s", destinationImage, err, result)
+	}
+
+	return nil
+}
+
+func runManifestCommand(containerTool string, args ...string) (string, error) {
+	command := exec.Command(containerTool, append([]string{"manifest"}, args...)...)
+	out, err := command.CombinedOutput()
+	return string(out), err
+}
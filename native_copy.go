@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/signature"
+	"github.com/containers/image/v5/transports/alltransports"
+	"github.com/containers/image/v5/types"
+)
+
+// CopyImageNative copies srcImage to destImage directly via the
+// containers/image library, without relying on a local docker/podman
+// daemon. It mirrors the resolve-policy-copy flow used by podman's
+// libimage migration: parse both references, build a permissive policy
+// context and stream copy progress to stdout. destCtx carries the
+// destination repository's auth config, if any (see BuildSystemContext).
+func CopyImageNative(srcImage string, destImage string, destCtx *types.SystemContext) error {
+	srcRef, err := alltransports.ParseImageName(withDefaultTransport(srcImage))
+	if err != nil {
+		return fmt.Errorf("parsing source image %q: %w", srcImage, err)
+	}
+
+	destRef, err := alltransports.ParseImageName(withDefaultTransport(destImage))
+	if err != nil {
+		return fmt.Errorf("parsing destination image %q: %w", destImage, err)
+	}
+
+	// signature.DefaultPolicy reads /etc/containers/policy.json, which this
+	// backend has no business requiring: it exists precisely so copies work
+	// on hosts without podman/skopeo (and the policy.json they ship)
+	// installed. Build the accept-anything policy in memory instead.
+	policy := &signature.Policy{
+		Default: signature.PolicyRequirements{signature.NewPRInsecureAcceptAnything()},
+	}
+
+	policyContext, err := signature.NewPolicyContext(policy)
+	if err != nil {
+		return fmt.Errorf("creating policy context: %w", err)
+	}
+	defer policyContext.Destroy()
+
+	_, err = copy.Image(context.Background(), policyContext, destRef, srcRef, &copy.Options{
+		ImageListSelection: copy.CopySystemImage,
+		ReportWriter:       os.Stdout,
+		DestinationCtx:     destCtx,
+	})
+	if err != nil {
+		return fmt.Errorf("copying %q to %q: %w", srcImage, destImage, err)
+	}
+
+	return nil
+}
+
+// withDefaultTransport prefixes image references that don't already
+// specify a transport (e.g. "docker-archive:", "dir:") with the
+// "docker://" transport, since that's the only one GenerateDestinationPathFromSourcePathAndConfig
+// currently produces.
+func withDefaultTransport(image string) string {
+	if HasTransportPrefix(image) {
+		return image
+	}
+
+	return "docker://" + image
+}
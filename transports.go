@@ -0,0 +1,20 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/containers/image/v5/transports"
+)
+
+// HasTransportPrefix reports whether image already names one of the
+// transports known to github.com/containers/image/v5 (docker-archive,
+// oci-archive, dir, containers-storage, ...), as opposed to a bare
+// registry reference like "alpine" or "myregistry.io/foo:tag".
+func HasTransportPrefix(image string) bool {
+	name, _, found := strings.Cut(image, ":")
+	if !found {
+		return false
+	}
+
+	return transports.Get(name) != nil
+}